@@ -0,0 +1,89 @@
+package microbatcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitJob_RejectWhenFullPolicy(t *testing.T) {
+	mockProcessor := &MockBatchProcessor[int]{}
+	mb := NewMicroBatching[int](mockProcessor, 10, time.Hour, time.Minute,
+		WithQueuePolicy[int](RejectWhenFullPolicy),
+		WithMaxQueueDepth[int](2),
+	)
+
+	_, err := mb.SubmitJob(Job[int]{ID: "1", Data: 1})
+	require.NoError(t, err)
+	_, err = mb.SubmitJob(Job[int]{ID: "2", Data: 2})
+	require.NoError(t, err)
+
+	_, err = mb.SubmitJob(Job[int]{ID: "3", Data: 3})
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.Equal(t, 2, mb.scheduler.len())
+}
+
+func TestSubmitJob_DropOldestPolicy(t *testing.T) {
+	mockProcessor := &MockBatchProcessor[int]{}
+	mb := NewMicroBatching[int](mockProcessor, 10, time.Hour, time.Minute,
+		WithQueuePolicy[int](DropOldestPolicy),
+		WithMaxQueueDepth[int](2),
+	)
+
+	oldest, err := mb.SubmitJob(Job[int]{ID: "1", Data: 1})
+	require.NoError(t, err)
+	_, err = mb.SubmitJob(Job[int]{ID: "2", Data: 2})
+	require.NoError(t, err)
+
+	// Queue is already at MaxQueueDepth; this submission should evict job "1".
+	_, err = mb.SubmitJob(Job[int]{ID: "3", Data: 3})
+	require.NoError(t, err)
+
+	select {
+	case result := <-oldest:
+		assert.False(t, result.Success)
+		assert.ErrorIs(t, result.Error, ErrQueueFull)
+	case <-time.After(time.Second):
+		t.Fatal("expected the oldest job to be dropped with ErrQueueFull")
+	}
+	assert.Equal(t, 2, mb.scheduler.len())
+}
+
+func TestSubmitJob_Coalescing(t *testing.T) {
+	mockProcessor := &MockBatchProcessor[int]{
+		ProcessFunc: func(jobs []Job[int]) []JobResult[int] {
+			results := make([]JobResult[int], len(jobs))
+			for i, job := range jobs {
+				results[i] = JobResult[int]{JobID: job.ID, Success: true, Result: job.Data}
+			}
+			return results
+		},
+	}
+	mb := NewMicroBatching[int](mockProcessor, 5, 50*time.Millisecond, time.Minute,
+		WithCoalescing[int](func(existing, incoming int) int { return existing + incoming }),
+	)
+
+	resultChanA, err := mb.SubmitJob(Job[int]{ID: "a", Data: 1, CoalesceKey: "entity-x"})
+	require.NoError(t, err)
+	resultChanB, err := mb.SubmitJob(Job[int]{ID: "b", Data: 2, CoalesceKey: "entity-x"})
+	require.NoError(t, err)
+
+	var resultA, resultB JobResult[int]
+	select {
+	case resultA = <-resultChanA:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for job a's result")
+	}
+	select {
+	case resultB = <-resultChanB:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for job b's result")
+	}
+
+	assert.Equal(t, "a", resultA.JobID)
+	assert.Equal(t, 3, resultA.Result)
+	assert.Equal(t, "b", resultB.JobID)
+	assert.Equal(t, 3, resultB.Result)
+}
@@ -0,0 +1,86 @@
+package microbatcher
+
+import (
+	"context"
+	"time"
+)
+
+// CtxBatchProcessor is an optional interface a BatchProcessor[T] may additionally
+// implement to cooperatively honor cancellation of jobs that have already been
+// pulled into a batch. ctx is cancelled when the MicroBatching system's parent
+// context (see WithContext) is cancelled, or when ShutdownWithGrace's grace
+// period elapses. Cancellation of an individual job's own context while it is
+// still queued is handled by MicroBatching itself (see SubmitJobCtx) and never
+// reaches the processor; use Job.Context to inspect a job's own context for
+// cancellation that occurs after the batch has started processing.
+type CtxBatchProcessor[T any] interface {
+	ProcessCtx(ctx context.Context, jobs []Job[T]) []JobResult[T]
+}
+
+// SubmitJobCtx submits job bound to ctx. If ctx is cancelled while the job is
+// still queued, it is dropped from the queue and a JobResult carrying ctx.Err()
+// as Error is delivered on the returned channel instead of ever being handed to
+// the processor. If cancellation happens after the job has already been pulled
+// into a batch, it is no longer dropped; instead ctx.Err() is only observable
+// cooperatively by a processor that implements CtxBatchProcessor[T] or that
+// inspects Job.Context.
+//
+// A job folded into another queued job via WithCoalescing is not dropped when
+// its own ctx is cancelled; it receives whatever result the job it was folded
+// into receives, including that job's cancellation.
+//
+// The watcher goroutine this spawns exits as soon as the job completes, even if
+// ctx is never cancelled (e.g. context.Background(), a valid argument here), so
+// submitting with a context that outlives the job does not leak a goroutine.
+func (mb *MicroBatching[T]) SubmitJobCtx(ctx context.Context, job Job[T]) (<-chan JobResult[T], error) {
+	job.ctx = ctx
+	resultChan, err := mb.SubmitJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	done := mb.jobDoneChan(job.ID)
+	go func() {
+		select {
+		case <-ctx.Done():
+			mb.cancelQueuedJob(job.ID, ctx.Err())
+		case <-done:
+			// Job already completed; nothing left to cancel.
+		}
+	}()
+
+	return resultChan, nil
+}
+
+// SubmitJobWithTimeout submits job bound to a context that is cancelled after
+// timeout elapses, per SubmitJobCtx semantics.
+func (mb *MicroBatching[T]) SubmitJobWithTimeout(job Job[T], timeout time.Duration) (<-chan JobResult[T], error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	resultChan, err := mb.SubmitJobCtx(ctx, job)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		cancel()
+	}()
+	return resultChan, nil
+}
+
+// cancelQueuedJob removes jobID from the scheduler if it is still queued and
+// delivers a JobResult carrying cause as Error to its subscriber. If the job
+// has already been picked up for batching (or already delivered a result),
+// this is a no-op: cancellation at that point is handled cooperatively by the
+// processor instead.
+func (mb *MicroBatching[T]) cancelQueuedJob(jobID string, cause error) {
+	if _, ok := mb.scheduler.remove(jobID); !ok {
+		return
+	}
+	<-mb.queueSem
+	mb.observer.JobDropped("context_cancelled")
+	mb.observer.QueueDepthObserved(mb.scheduler.len())
+
+	mb.logger.Debugf("Job %s cancelled while queued: %v", jobID, cause)
+	mb.deliverResult(jobID, JobResult[T]{Success: false, Error: cause, Time: time.Now()})
+}
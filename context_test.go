@@ -0,0 +1,95 @@
+package microbatcher
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitJobCtx_CancelledWhileQueued(t *testing.T) {
+	mockProcessor := &MockBatchProcessor[int]{
+		ProcessFunc: func(jobs []Job[int]) []JobResult[int] {
+			results := make([]JobResult[int], len(jobs))
+			for i, job := range jobs {
+				results[i] = JobResult[int]{JobID: job.ID, Success: true, Result: job.Data}
+			}
+			return results
+		},
+	}
+	// batchSize 2 so the job submitted below never reaches batchSize on its own
+	// and the long batchInterval ensures it is still queued when cancelled.
+	mb := NewMicroBatching[int](mockProcessor, 2, time.Hour, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resultChan, err := mb.SubmitJobCtx(ctx, Job[int]{ID: "cancel-me", Data: 1})
+	require.NoError(t, err)
+
+	cancel()
+
+	select {
+	case result := <-resultChan:
+		assert.False(t, result.Success)
+		assert.ErrorIs(t, result.Error, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for cancellation result")
+	}
+}
+
+func TestSubmitJobWithTimeout_Expires(t *testing.T) {
+	mockProcessor := &MockBatchProcessor[int]{}
+	mb := NewMicroBatching[int](mockProcessor, 2, time.Hour, time.Second)
+
+	resultChan, err := mb.SubmitJobWithTimeout(Job[int]{ID: "timeout-me", Data: 1}, 20*time.Millisecond)
+	require.NoError(t, err)
+
+	select {
+	case result := <-resultChan:
+		assert.False(t, result.Success)
+		assert.ErrorIs(t, result.Error, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for deadline-exceeded result")
+	}
+}
+
+func TestJob_ContextDefaultsToBackground(t *testing.T) {
+	job := Job[int]{ID: "plain"}
+	assert.Equal(t, context.Background(), job.Context())
+}
+
+func TestSubmitJobCtx_WatcherExitsWhenContextNeverCancelled(t *testing.T) {
+	mockProcessor := &MockBatchProcessor[int]{
+		ProcessFunc: func(jobs []Job[int]) []JobResult[int] {
+			results := make([]JobResult[int], len(jobs))
+			for i, job := range jobs {
+				results[i] = JobResult[int]{JobID: job.ID, Success: true, Result: job.Data}
+			}
+			return results
+		},
+	}
+	mb := NewMicroBatching[int](mockProcessor, 1, 10*time.Millisecond, time.Minute)
+
+	before := runtime.NumGoroutine()
+
+	// context.Background() never cancels; the watcher goroutine SubmitJobCtx
+	// spawns must still exit once the job completes instead of leaking.
+	resultChan, err := mb.SubmitJobCtx(context.Background(), Job[int]{ID: "never-cancelled", Data: 1})
+	require.NoError(t, err)
+	<-resultChan
+
+	// Poll runtime.NumGoroutine() directly rather than via require.Eventually:
+	// Eventually runs its condition func inside a goroutine of its own, which
+	// would permanently inflate the count it's trying to measure.
+	after := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		if after <= before {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+		after = runtime.NumGoroutine()
+	}
+	assert.LessOrEqual(t, after, before, "watcher goroutine leaked past job completion")
+}
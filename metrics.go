@@ -0,0 +1,160 @@
+package microbatcher
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Observer receives structured observability events from a MicroBatching
+// system. Implementations must be safe for concurrent use: methods are called
+// from job submission and batch processing goroutines alike.
+type Observer interface {
+	// JobSubmitted is called once a job has been accepted onto the queue.
+	JobSubmitted()
+	// JobDropped is called when a job is removed from the queue without ever
+	// reaching the processor, e.g. because its context was cancelled.
+	JobDropped(reason string)
+	// JobSucceeded and JobFailed are called once per job, after a batch
+	// containing it has been processed, based on JobResult.Success.
+	JobSucceeded()
+	JobFailed()
+	// QueueWaitObserved reports how long a job sat queued before entering a batch.
+	QueueWaitObserved(d time.Duration)
+	// BatchObserved reports the size and processing latency of a completed
+	// batch, along with what triggered it draining: "size", "ticker" or
+	// "shutdown".
+	BatchObserved(size int, trigger string, d time.Duration)
+	// QueueDepthObserved reports the current number of jobs queued but not yet
+	// in a batch.
+	QueueDepthObserved(depth int)
+	// OutstandingResultsObserved reports the current number of results awaiting
+	// consumption.
+	OutstandingResultsObserved(count int)
+	// ResultsExpired reports how many consumed results were cleaned up after
+	// their TTL in a single cleanup pass.
+	ResultsExpired(count int)
+}
+
+// noopObserver is the default Observer: it discards every observation.
+type noopObserver struct{}
+
+func (noopObserver) JobSubmitted()                                          {}
+func (noopObserver) JobDropped(reason string)                               {}
+func (noopObserver) JobSucceeded()                                          {}
+func (noopObserver) JobFailed()                                             {}
+func (noopObserver) QueueWaitObserved(d time.Duration)                      {}
+func (noopObserver) BatchObserved(size int, trigger string, d time.Duration) {}
+func (noopObserver) QueueDepthObserved(depth int)                          {}
+func (noopObserver) OutstandingResultsObserved(count int)                  {}
+func (noopObserver) ResultsExpired(count int)                              {}
+
+// PrometheusObserver is an Observer backed by Prometheus metrics.
+type PrometheusObserver struct {
+	jobsSubmitted  prometheus.Counter
+	jobsDropped    *prometheus.CounterVec
+	jobsSucceeded  prometheus.Counter
+	jobsFailed     prometheus.Counter
+	queueWait      prometheus.Histogram
+	batchSize      prometheus.Histogram
+	batchLatency   *prometheus.HistogramVec
+	resultsExpired prometheus.Counter
+	queueDepth     prometheus.Gauge
+	outstanding    prometheus.Gauge
+}
+
+// NewPrometheusObserver builds a PrometheusObserver and registers its metrics
+// against reg.
+func NewPrometheusObserver(reg prometheus.Registerer) *PrometheusObserver {
+	o := &PrometheusObserver{
+		jobsSubmitted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "microbatcher_jobs_submitted_total",
+			Help: "Total number of jobs submitted.",
+		}),
+		jobsDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "microbatcher_jobs_dropped_total",
+			Help: "Total number of jobs dropped before processing, by reason.",
+		}, []string{"reason"}),
+		jobsSucceeded: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "microbatcher_jobs_succeeded_total",
+			Help: "Total number of jobs that completed successfully.",
+		}),
+		jobsFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "microbatcher_jobs_failed_total",
+			Help: "Total number of jobs that completed with an error.",
+		}),
+		queueWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "microbatcher_queue_wait_seconds",
+			Help: "Time jobs spend queued before entering a batch.",
+		}),
+		batchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "microbatcher_batch_size",
+			Help:    "Number of jobs per processed batch.",
+			Buckets: prometheus.LinearBuckets(1, 2, 10),
+		}),
+		batchLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "microbatcher_batch_processing_seconds",
+			Help: "Time spent processing a batch, by trigger (size, ticker or shutdown).",
+		}, []string{"trigger"}),
+		resultsExpired: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "microbatcher_results_expired_total",
+			Help: "Total number of consumed results cleaned up after their TTL.",
+		}),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "microbatcher_queue_depth",
+			Help: "Current number of jobs queued but not yet in a batch.",
+		}),
+		outstanding: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "microbatcher_outstanding_results",
+			Help: "Current number of results awaiting consumption.",
+		}),
+	}
+	reg.MustRegister(
+		o.jobsSubmitted, o.jobsDropped, o.jobsSucceeded, o.jobsFailed,
+		o.queueWait, o.batchSize, o.batchLatency, o.resultsExpired,
+		o.queueDepth, o.outstanding,
+	)
+	return o
+}
+
+func (o *PrometheusObserver) JobSubmitted() { o.jobsSubmitted.Inc() }
+
+func (o *PrometheusObserver) JobDropped(reason string) { o.jobsDropped.WithLabelValues(reason).Inc() }
+
+func (o *PrometheusObserver) JobSucceeded() { o.jobsSucceeded.Inc() }
+
+func (o *PrometheusObserver) JobFailed() { o.jobsFailed.Inc() }
+
+func (o *PrometheusObserver) QueueWaitObserved(d time.Duration) { o.queueWait.Observe(d.Seconds()) }
+
+func (o *PrometheusObserver) BatchObserved(size int, trigger string, d time.Duration) {
+	o.batchSize.Observe(float64(size))
+	o.batchLatency.WithLabelValues(trigger).Observe(d.Seconds())
+}
+
+func (o *PrometheusObserver) QueueDepthObserved(depth int) { o.queueDepth.Set(float64(depth)) }
+
+func (o *PrometheusObserver) OutstandingResultsObserved(count int) {
+	o.outstanding.Set(float64(count))
+}
+
+func (o *PrometheusObserver) ResultsExpired(count int) { o.resultsExpired.Add(float64(count)) }
+
+// WithObserver sets the Observer used to record job and batch metrics. The
+// default is a no-op Observer.
+func WithObserver[T any](o Observer) Option[T] {
+	return func(mb *MicroBatching[T]) {
+		mb.observer = o
+	}
+}
+
+// WithTracer sets the OpenTelemetry tracer used to create a span for each job,
+// from submission through result delivery, and a parent span for each batch
+// with "batch.size" and "batch.trigger" attributes. The default is a no-op
+// tracer, so tracing has no effect unless this is set.
+func WithTracer[T any](tracer trace.Tracer) Option[T] {
+	return func(mb *MicroBatching[T]) {
+		mb.tracer = tracer
+	}
+}
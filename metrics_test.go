@@ -0,0 +1,92 @@
+package microbatcher
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeObserver records every call made to it, for assertions in tests.
+type fakeObserver struct {
+	mu          sync.Mutex
+	submitted   int
+	dropped     []string
+	succeeded   int
+	failed      int
+	batches     []int
+	expired     int
+}
+
+func (f *fakeObserver) JobSubmitted() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submitted++
+}
+
+func (f *fakeObserver) JobDropped(reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dropped = append(f.dropped, reason)
+}
+
+func (f *fakeObserver) JobSucceeded() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.succeeded++
+}
+
+func (f *fakeObserver) JobFailed() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failed++
+}
+
+func (f *fakeObserver) QueueWaitObserved(d time.Duration) {}
+
+func (f *fakeObserver) BatchObserved(size int, trigger string, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, size)
+}
+
+func (f *fakeObserver) QueueDepthObserved(depth int) {}
+
+func (f *fakeObserver) OutstandingResultsObserved(count int) {}
+
+func (f *fakeObserver) ResultsExpired(count int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expired += count
+}
+
+func TestWithObserver_RecordsSubmissionAndBatchOutcome(t *testing.T) {
+	mockProcessor := &MockBatchProcessor[int]{
+		ProcessFunc: func(jobs []Job[int]) []JobResult[int] {
+			results := make([]JobResult[int], len(jobs))
+			for i, job := range jobs {
+				results[i] = JobResult[int]{JobID: job.ID, Success: job.Data != 0, Result: job.Data}
+			}
+			return results
+		},
+	}
+	observer := &fakeObserver{}
+	mb := NewMicroBatching[int](mockProcessor, 2, 10*time.Millisecond, time.Second, WithObserver[int](observer))
+
+	resultChan1, err := mb.SubmitJob(Job[int]{ID: "ok", Data: 1})
+	require.NoError(t, err)
+	resultChan2, err := mb.SubmitJob(Job[int]{ID: "bad", Data: 0})
+	require.NoError(t, err)
+
+	<-resultChan1
+	<-resultChan2
+
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	assert.Equal(t, 2, observer.submitted)
+	assert.Equal(t, 1, observer.succeeded)
+	assert.Equal(t, 1, observer.failed)
+	assert.NotEmpty(t, observer.batches)
+}
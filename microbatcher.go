@@ -1,8 +1,11 @@
 package microbatcher
 
 import (
+	"context"
 	"errors"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"sync"
 	"time"
 )
@@ -11,6 +14,39 @@ import (
 type Job[T any] struct {
 	ID   string
 	Data T
+
+	// Priority controls draining order under PriorityPolicy; higher values drain
+	// first. It is ignored under FIFOPolicy and EDFPolicy.
+	Priority int
+
+	// Deadline, if set, is the time by which the job should ideally be processed.
+	// It is only consulted under EDFPolicy.
+	Deadline time.Time
+
+	// CoalesceKey, if non-empty, folds this job into any other still-queued job
+	// sharing the same key instead of enqueuing it separately, via the merge
+	// function passed to WithCoalescing. It has no effect unless WithCoalescing
+	// is configured.
+	CoalesceKey string
+
+	// ctx is the context the job was submitted with via SubmitJobCtx or
+	// SubmitJobWithTimeout. It is nil for jobs submitted via plain SubmitJob.
+	ctx context.Context
+
+	// submittedAt and span back the queue-wait metric and the job's tracing
+	// span; both are set by SubmitJob once the job is accepted onto the queue.
+	submittedAt time.Time
+	span        trace.Span
+}
+
+// Context returns the context job was submitted with via SubmitJobCtx or
+// SubmitJobWithTimeout. Jobs submitted via plain SubmitJob carry
+// context.Background().
+func (j Job[T]) Context() context.Context {
+	if j.ctx == nil {
+		return context.Background()
+	}
+	return j.ctx
 }
 
 // JobResult represents the result of a processed job.
@@ -24,41 +60,151 @@ type JobResult[T any] struct {
 }
 
 // BatchProcessor is an interface that should be implemented by the user of the library.
+// Processors that want to emit staged progress instead of only a terminal result
+// can additionally implement StreamingBatchProcessor[T].
 type BatchProcessor[T any] interface {
 	Process(jobs []Job[T]) []JobResult[T]
 }
 
 // MicroBatching represents the micro-batching system.
 type MicroBatching[T any] struct {
-	processor      BatchProcessor[T]
-	batchSize      int
-	batchInterval  time.Duration
-	jobQueue       chan Job[T]
-	resultQueue    chan JobResult[T]
-	shutdownFlag   chan struct{}
-	shutdownWG     sync.WaitGroup
-	results        map[string]chan JobResult[T]
-	resultsMutex   sync.Mutex
-	resultTTL      time.Duration
-	cleanupTicker  *time.Ticker
-	cleanupStopper chan struct{}
-	logger         *logrus.Logger
+	processor        BatchProcessor[T]
+	batchSize        int
+	batchInterval    time.Duration
+	schedulingPolicy SchedulingPolicy
+	scheduler        *scheduler[T]
+	queuePolicy      QueuePolicy
+	maxQueueDepth    int
+	queueSem         chan struct{}
+	jobNotify        chan struct{}
+	resultQueue      chan JobResult[T]
+	shutdownFlag     chan struct{}
+	shutdownWG       sync.WaitGroup
+	results          map[string]chan JobResult[T]
+	resultsMutex     sync.Mutex
+	jobDone          map[string]chan struct{}
+	coalesceFunc     func(existing, incoming T) T
+	coalesced        map[string][]string
+	events           map[string]chan JobEvent[T]
+	eventsMutex      sync.Mutex
+	resultTTL        time.Duration
+	cleanupTicker    *time.Ticker
+	cleanupStopper   chan struct{}
+	logger           *logrus.Logger
+	parentCtx        context.Context
+	ctx              context.Context
+	cancel           context.CancelFunc
+	observer         Observer
+	tracer           trace.Tracer
+	resultStore      ResultStore[T]
+}
+
+// Option configures optional behavior on a MicroBatching system.
+type Option[T any] func(*MicroBatching[T])
+
+// WithSchedulingPolicy selects the policy used to order jobs drained from the
+// internal queue. The default is FIFOPolicy, matching historical behavior.
+func WithSchedulingPolicy[T any](policy SchedulingPolicy) Option[T] {
+	return func(mb *MicroBatching[T]) {
+		mb.schedulingPolicy = policy
+	}
+}
+
+// WithContext sets the parent context for the MicroBatching system. Cancelling
+// it, or ShutdownWithGrace's grace period elapsing, cancels the context passed
+// to CtxBatchProcessor.ProcessCtx for any batch still in flight. Defaults to
+// context.Background().
+func WithContext[T any](ctx context.Context) Option[T] {
+	return func(mb *MicroBatching[T]) {
+		mb.parentCtx = ctx
+	}
+}
+
+// QueuePolicy selects how SubmitJob behaves when the queue is already at
+// MaxQueueDepth.
+type QueuePolicy int
+
+const (
+	// BlockPolicy makes SubmitJob block until a slot frees up. This is the
+	// default and matches historical behavior.
+	BlockPolicy QueuePolicy = iota
+	// RejectWhenFullPolicy makes SubmitJob return ErrQueueFull immediately
+	// instead of blocking.
+	RejectWhenFullPolicy
+	// DropOldestPolicy makes SubmitJob evict the least-recently-submitted
+	// still-queued job to make room, delivering it a JobResult carrying
+	// ErrQueueFull as Error instead of ever handing it to the processor.
+	DropOldestPolicy
+)
+
+// ErrQueueFull is returned by SubmitJob under RejectWhenFullPolicy when the
+// queue is already at MaxQueueDepth, and carried as the Error of a job dropped
+// to make room under DropOldestPolicy.
+var ErrQueueFull = errors.New("microbatcher: queue is full")
+
+// WithQueuePolicy selects how SubmitJob behaves when the queue is already at
+// MaxQueueDepth. The default is BlockPolicy, matching historical behavior.
+func WithQueuePolicy[T any](policy QueuePolicy) Option[T] {
+	return func(mb *MicroBatching[T]) {
+		mb.queuePolicy = policy
+	}
+}
+
+// WithMaxQueueDepth caps the number of jobs that may be queued awaiting
+// batching, independent of batchSize. The default is batchSize*10, matching
+// historical behavior.
+func WithMaxQueueDepth[T any](depth int) Option[T] {
+	return func(mb *MicroBatching[T]) {
+		mb.maxQueueDepth = depth
+	}
+}
+
+// WithCoalescing folds multiple queued jobs that share the same non-empty
+// Job.CoalesceKey into one before dispatch: merge is called with the existing
+// queued job's Data first and each newly submitted job's Data second, and its
+// result becomes the merged job's Data. The merged job is dispatched to the
+// processor exactly once, and every original submitter still gets its own
+// JobResult back from SubmitJob (and GetResult), addressed by its own Job.ID.
+// Jobs with an empty CoalesceKey are never coalesced. There is no coalescing by
+// default.
+func WithCoalescing[T any](merge func(existing, incoming T) T) Option[T] {
+	return func(mb *MicroBatching[T]) {
+		mb.coalesceFunc = merge
+	}
 }
 
 // NewMicroBatching creates a new instance of the MicroBatching system.
-func NewMicroBatching[T any](processor BatchProcessor[T], batchSize int, batchInterval, resultTTL time.Duration) *MicroBatching[T] {
+func NewMicroBatching[T any](processor BatchProcessor[T], batchSize int, batchInterval, resultTTL time.Duration, opts ...Option[T]) *MicroBatching[T] {
 	mb := &MicroBatching[T]{
-		processor:      processor,
-		batchSize:      batchSize,
-		batchInterval:  batchInterval,
-		jobQueue:       make(chan Job[T], batchSize*10),
-		resultQueue:    make(chan JobResult[T], batchSize*10),
-		shutdownFlag:   make(chan struct{}),
-		results:        make(map[string]chan JobResult[T]),
-		resultTTL:      resultTTL,
-		cleanupStopper: make(chan struct{}),
-		logger:         logrus.New(),
+		processor:        processor,
+		batchSize:        batchSize,
+		batchInterval:    batchInterval,
+		schedulingPolicy: FIFOPolicy,
+		queuePolicy:      BlockPolicy,
+		resultQueue:      make(chan JobResult[T], batchSize*10),
+		shutdownFlag:     make(chan struct{}),
+		results:          make(map[string]chan JobResult[T]),
+		jobDone:          make(map[string]chan struct{}),
+		events:           make(map[string]chan JobEvent[T]),
+		coalesced:        make(map[string][]string),
+		resultTTL:        resultTTL,
+		cleanupStopper:   make(chan struct{}),
+		logger:           logrus.New(),
+		parentCtx:        context.Background(),
+		observer:         noopObserver{},
+		tracer:           trace.NewNoopTracerProvider().Tracer("microbatcher"),
+		resultStore:      NewInMemoryResultStore[T](),
+	}
+	for _, opt := range opts {
+		opt(mb)
 	}
+	if mb.maxQueueDepth <= 0 {
+		mb.maxQueueDepth = batchSize * 10
+	}
+	mb.ctx, mb.cancel = context.WithCancel(mb.parentCtx)
+	mb.scheduler = newScheduler[T](mb.schedulingPolicy)
+	mb.queueSem = make(chan struct{}, mb.maxQueueDepth)
+	mb.jobNotify = make(chan struct{}, 1)
 	mb.logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
 	mb.logger.SetLevel(logrus.DebugLevel) // Set log level to Debug
 	mb.shutdownWG.Add(1)
@@ -83,29 +229,22 @@ func (mb *MicroBatching[T]) cleanupResults() {
 	}
 }
 
-// cleanupExpiredResults handles the logic of cleaning up expired results.
+// cleanupExpiredResults asks the configured ResultStore to drop every consumed
+// result older than resultTTL.
 func (mb *MicroBatching[T]) cleanupExpiredResults() {
-	now := time.Now()
-	mb.resultsMutex.Lock()
-	defer mb.resultsMutex.Unlock()
-	for id, resultChan := range mb.results {
-		select {
-		case res := <-resultChan:
-			if now.Sub(res.Time) > mb.resultTTL && res.Consumed {
-				mb.logger.Debugf("Result for JobID %s is expired and cleaned up", id)
-				close(resultChan)
-				delete(mb.results, id)
-			} else {
-				// If result is not expired or not consumed, put it back in the channel
-				resultChan <- res
-			}
-		default:
-			continue
-		}
+	expired, err := mb.resultStore.DeleteExpired(mb.resultTTL)
+	if err != nil {
+		mb.logger.Errorf("Failed to delete expired results: %v", err)
+		return
+	}
+	if expired > 0 {
+		mb.logger.Debugf("Cleaned up %d expired results", expired)
 	}
+	mb.observer.ResultsExpired(expired)
 }
 
-// SubmitJob submits a job to the micro-batching system.
+// SubmitJob submits a job to the micro-batching system. How it behaves once the
+// queue is already at MaxQueueDepth is governed by the configured QueuePolicy.
 func (mb *MicroBatching[T]) SubmitJob(job Job[T]) (<-chan JobResult[T], error) {
 	mb.logger.Debugf("Attempting to submit job: %+v", job)
 
@@ -115,57 +254,194 @@ func (mb *MicroBatching[T]) SubmitJob(job Job[T]) (<-chan JobResult[T], error) {
 		mb.logger.Warn("System is shutting down, cannot accept new jobs")
 		return nil, errors.New("system is shutting down, cannot accept new jobs")
 	default:
-		// Proceed to attempt to enqueue the job
+	}
+
+	switch mb.queuePolicy {
+	case RejectWhenFullPolicy:
+		select {
+		case mb.queueSem <- struct{}{}:
+		default:
+			mb.observer.JobDropped("queue_full")
+			return nil, ErrQueueFull
+		}
+	case DropOldestPolicy:
+		select {
+		case mb.queueSem <- struct{}{}:
+		default:
+			mb.dropOldestQueuedJob()
+			mb.queueSem <- struct{}{}
+		}
+	default: // BlockPolicy
 		select {
-		case mb.jobQueue <- job:
-			mb.shutdownWG.Add(1) // Increment the WaitGroup counter
-			// Only create the channel and map entry after successfully enqueuing the job
-			resultChan := make(chan JobResult[T], 1)
-			mb.resultsMutex.Lock()
-			mb.results[job.ID] = resultChan
-			mb.resultsMutex.Unlock()
-			mb.logger.Debugf("Job %s added to job queue", job.ID)
-			go func() {
-				// Wait for the result to be processed and sent
-				<-resultChan
-				mb.shutdownWG.Done() // Decrement the WaitGroup counter
-			}()
-			return resultChan, nil
+		case mb.queueSem <- struct{}{}:
 		case <-mb.shutdownFlag:
 			// In case the system starts shutting down right after the initial check
 			mb.logger.Warn("System started shutting down, cannot accept new jobs")
 			return nil, errors.New("system started shutting down, cannot accept new jobs")
 		}
 	}
+
+	job.submittedAt = time.Now()
+	_, job.span = mb.tracer.Start(job.Context(), "microbatcher.job", trace.WithAttributes(
+		attribute.String("job.id", job.ID),
+	))
+	canonicalID, coalesced := mb.scheduler.push(job, mb.coalesceFunc)
+	if coalesced {
+		// job was folded into an already-queued job instead of occupying a queue
+		// slot of its own.
+		<-mb.queueSem
+	}
+	mb.signalNewJob()
+	mb.observer.JobSubmitted()
+	mb.observer.QueueDepthObserved(mb.scheduler.len())
+	mb.shutdownWG.Add(1) // Increment the WaitGroup counter
+	// Only create the channel and map entry after successfully enqueuing the job
+	resultChan := make(chan JobResult[T], 1)
+	doneChan := make(chan struct{})
+	mb.resultsMutex.Lock()
+	mb.results[job.ID] = resultChan
+	mb.jobDone[job.ID] = doneChan
+	if coalesced {
+		mb.coalesced[canonicalID] = append(mb.coalesced[canonicalID], job.ID)
+	}
+	mb.observer.OutstandingResultsObserved(len(mb.results))
+	mb.resultsMutex.Unlock()
+	mb.logger.Debugf("Job %s added to job queue", job.ID)
+	go func() {
+		// Wait for the result to be delivered, without consuming it from
+		// resultChan itself — that channel is the caller's to read.
+		<-doneChan
+		job.span.End()
+		mb.shutdownWG.Done() // Decrement the WaitGroup counter
+	}()
+	return resultChan, nil
+}
+
+// dropOldestQueuedJob evicts the least-recently-submitted still-queued job to
+// make room under DropOldestPolicy, delivering it a JobResult carrying
+// ErrQueueFull as Error. It reports false if the queue was already empty.
+func (mb *MicroBatching[T]) dropOldestQueuedJob() bool {
+	job, ok := mb.scheduler.popOldest()
+	if !ok {
+		return false
+	}
+	<-mb.queueSem
+	mb.observer.JobDropped("queue_full")
+	mb.observer.QueueDepthObserved(mb.scheduler.len())
+	mb.logger.Warnf("Dropping oldest queued job %s to make room under DropOldestPolicy", job.ID)
+	mb.deliverResult(job.ID, JobResult[T]{Success: false, Error: ErrQueueFull, Time: time.Now()})
+	return true
+}
+
+// deliverResult stores result under jobID and every job folded into jobID via
+// WithCoalescing, marking each stored copy consumed so it becomes eligible for
+// ResultStore.DeleteExpired once resultTTL elapses, and sends it (with JobID set
+// to each recipient's own ID) to each one's subscriber channel, closing it. For
+// any of those IDs that has no subscriber channel registered, the result is
+// still stored but nothing is sent.
+func (mb *MicroBatching[T]) deliverResult(jobID string, result JobResult[T]) {
+	mb.resultsMutex.Lock()
+	defer mb.resultsMutex.Unlock()
+
+	recipients := append([]string{jobID}, mb.coalesced[jobID]...)
+	delete(mb.coalesced, jobID)
+	for _, id := range recipients {
+		r := result
+		r.JobID = id
+		if err := mb.resultStore.Put(r); err != nil {
+			mb.logger.Errorf("Failed to persist result for JobID %s: %v", id, err)
+		} else if err := mb.resultStore.MarkConsumed(id); err != nil {
+			mb.logger.Errorf("Failed to mark result consumed for JobID %s: %v", id, err)
+		}
+		if resultChan, exists := mb.results[id]; exists {
+			mb.logger.Debugf("Sending result for JobID %s: %+v", id, r)
+			resultChan <- r
+			close(resultChan)
+			delete(mb.results, id)
+		}
+		if doneChan, exists := mb.jobDone[id]; exists {
+			close(doneChan)
+			delete(mb.jobDone, id)
+		}
+	}
+	mb.observer.OutstandingResultsObserved(len(mb.results))
+}
+
+// jobDoneChan returns the completion signal registered for jobID by SubmitJob,
+// closed by deliverResult once the job's result has been delivered. If jobID has
+// already been delivered (and its entry removed), it returns an already-closed
+// channel so callers never block waiting on a job that is done.
+func (mb *MicroBatching[T]) jobDoneChan(jobID string) <-chan struct{} {
+	mb.resultsMutex.Lock()
+	defer mb.resultsMutex.Unlock()
+	if doneChan, ok := mb.jobDone[jobID]; ok {
+		return doneChan
+	}
+	closed := make(chan struct{})
+	close(closed)
+	return closed
 }
 
-// processBatches processes jobs in batches and sends results to the resultQueue.
+// signalNewJob wakes processBatches if it is idle, without blocking if it is
+// already aware of pending work.
+func (mb *MicroBatching[T]) signalNewJob() {
+	select {
+	case mb.jobNotify <- struct{}{}:
+	default:
+	}
+}
+
+// popBatch drains up to n jobs from the scheduler in policy order, releasing one
+// queueSem slot and recording queue-wait time per job popped. The submittedAt
+// and span bookkeeping fields are cleared before a job is returned: submittedAt
+// has been consumed by the metric above, the span is ended later by the
+// goroutine SubmitJob spawned (which holds its own copy of the job), and leaving
+// them set would make the Job[T] handed to BatchProcessor.Process differ from
+// the one the caller originally submitted.
+func (mb *MicroBatching[T]) popBatch(n int) []Job[T] {
+	jobs := make([]Job[T], 0, n)
+	for len(jobs) < n {
+		job, ok := mb.scheduler.pop()
+		if !ok {
+			break
+		}
+		<-mb.queueSem
+		mb.observer.QueueWaitObserved(time.Since(job.submittedAt))
+		job.submittedAt = time.Time{}
+		job.span = nil
+		jobs = append(jobs, job)
+	}
+	mb.observer.QueueDepthObserved(mb.scheduler.len())
+	return jobs
+}
+
+// processBatches pops up to batchSize jobs from the scheduler per tick, in the
+// order dictated by the configured SchedulingPolicy, and sends results to the
+// resultQueue.
 func (mb *MicroBatching[T]) processBatches() {
 	defer mb.shutdownWG.Done()
 	ticker := time.NewTicker(mb.batchInterval)
 	defer ticker.Stop()
 
-	var jobs []Job[T]
 	for {
 		select {
-		case job := <-mb.jobQueue:
-			mb.logger.Debugf("Received job: %+v", job)
-			jobs = append(jobs, job)
-			if len(jobs) >= mb.batchSize {
+		case <-mb.jobNotify:
+			for mb.scheduler.len() >= mb.batchSize {
+				jobs := mb.popBatch(mb.batchSize)
 				mb.logger.Debugf("Processing batch of %d jobs", len(jobs))
-				mb.processAndSendResults(jobs)
-				jobs = nil
+				mb.processAndSendResults(jobs, "size")
 			}
 		case <-ticker.C:
-			if len(jobs) > 0 {
+			if mb.scheduler.len() > 0 {
+				jobs := mb.popBatch(mb.batchSize)
 				mb.logger.Debugf("Processing batch of %d jobs due to ticker", len(jobs))
-				mb.processAndSendResults(jobs)
-				jobs = nil
+				mb.processAndSendResults(jobs, "ticker")
 			}
 		case <-mb.shutdownFlag:
-			if len(jobs) > 0 {
+			for mb.scheduler.len() > 0 {
+				jobs := mb.popBatch(mb.batchSize)
 				mb.logger.Debugf("Shutting down after %d remaining jobs processed", len(jobs))
-				mb.processAndSendResults(jobs)
+				mb.processAndSendResults(jobs, "shutdown")
 			}
 			return
 		}
@@ -173,31 +449,80 @@ func (mb *MicroBatching[T]) processBatches() {
 }
 
 // processAndSendResults processes a batch of jobs and sends results to the resultQueue.
-func (mb *MicroBatching[T]) processAndSendResults(jobs []Job[T]) {
-	results := mb.processor.Process(jobs)
+// If the processor also implements StreamingBatchProcessor[T], it is used instead
+// of Process so that per-job Stage/Log/Progress events are fanned out to any
+// subscribers registered via SubmitJobStream. Otherwise, if the processor
+// implements CtxBatchProcessor[T], it is used so the batch can cooperatively
+// honor cancellation of the system's context (see WithContext, ShutdownWithGrace).
+// trigger records what caused the batch to drain ("size", "ticker", or
+// "shutdown") and is attached to the batch's span and latency metric.
+func (mb *MicroBatching[T]) processAndSendResults(jobs []Job[T], trigger string) {
+	batchCtx, batchSpan := mb.tracer.Start(mb.ctx, "microbatcher.batch", trace.WithAttributes(
+		attribute.Int("batch.size", len(jobs)),
+		attribute.String("batch.trigger", trigger),
+	))
+	start := time.Now()
 
-	mb.resultsMutex.Lock()
-	defer mb.resultsMutex.Unlock()
+	var results []JobResult[T]
+	switch p := mb.processor.(type) {
+	case StreamingBatchProcessor[T]:
+		results = mb.processStreamingBatch(batchCtx, p, jobs)
+	case CtxBatchProcessor[T]:
+		results = p.ProcessCtx(batchCtx, jobs)
+	default:
+		results = mb.processor.Process(jobs)
+	}
+
+	batchSpan.End()
+	mb.observer.BatchObserved(len(jobs), trigger, time.Since(start))
 
 	for i, result := range results {
 		result.Time = time.Now()
 		result.Consumed = false
-		if resultChan, exists := mb.results[jobs[i].ID]; exists {
-			mb.logger.Debugf("Sending result for JobID %s: %+v", jobs[i].ID, result)
-			resultChan <- result
-			close(resultChan)
-			delete(mb.results, jobs[i].ID)
+		if result.Success {
+			mb.observer.JobSucceeded()
+		} else {
+			mb.observer.JobFailed()
 		}
+		mb.deliverResult(jobs[i].ID, result)
 	}
 }
 
-// Shutdown gracefully shuts down the micro-batching system.
+// Shutdown gracefully shuts down the micro-batching system, waiting
+// indefinitely for any in-flight batch to finish. Use ShutdownWithGrace to
+// bound how long it waits before force-cancelling in-flight work.
 func (mb *MicroBatching[T]) Shutdown() {
+	mb.ShutdownWithGrace(0)
+}
+
+// ShutdownWithGrace shuts down the micro-batching system, waiting up to grace
+// for any in-flight batch to finish. If grace elapses first, the context
+// passed to CtxBatchProcessor.ProcessCtx is cancelled so the in-flight batch
+// can cooperatively abandon its work; ShutdownWithGrace still waits for it to
+// return afterwards. grace <= 0 waits indefinitely, matching Shutdown.
+func (mb *MicroBatching[T]) ShutdownWithGrace(grace time.Duration) {
 	mb.logger.Info("Shutting down MicroBatching system")
 	close(mb.shutdownFlag)
-	mb.shutdownWG.Wait()
 
+	done := make(chan struct{})
+	go func() {
+		mb.shutdownWG.Wait()
+		close(done)
+	}()
+
+	if grace <= 0 {
+		<-done
+	} else {
+		select {
+		case <-done:
+		case <-time.After(grace):
+			mb.logger.Warn("Shutdown grace period elapsed, cancelling in-flight work")
+			mb.cancel()
+			<-done
+		}
+	}
+
+	mb.cancel()
 	close(mb.resultQueue)
-	close(mb.jobQueue)
 	mb.cleanupStopper <- struct{}{}
 }
@@ -33,8 +33,11 @@ func TestNewMicroBatching(t *testing.T) {
 	assert.Equal(t, batchSize, mb.batchSize)
 	assert.Equal(t, batchInterval, mb.batchInterval)
 	assert.Equal(t, resultTTL, mb.resultTTL)
-	assert.NotNil(t, mb.jobQueue)
+	assert.NotNil(t, mb.scheduler)
 	assert.NotNil(t, mb.resultQueue)
+	assert.NotNil(t, mb.resultStore)
+	assert.NotNil(t, mb.coalesced)
+	assert.NotNil(t, mb.jobDone)
 	assert.NotNil(t, mb.shutdownFlag)
 	assert.NotNil(t, mb.results)
 	assert.NotNil(t, mb.cleanupStopper)
@@ -197,8 +200,7 @@ func TestCleanupExpiredResults(t *testing.T) {
 		ProcessFunc: func(jobs []Job[int]) []JobResult[int] {
 			results := make([]JobResult[int], len(jobs))
 			for i, job := range jobs {
-				// Set the result time to the past to simulate expiration
-				results[i] = JobResult[int]{JobID: job.ID, Success: true, Result: job.Data, Time: time.Now().Add(-2 * time.Hour), Consumed: false}
+				results[i] = JobResult[int]{JobID: job.ID, Success: true, Result: job.Data}
 			}
 			return results
 		},
@@ -207,19 +209,19 @@ func TestCleanupExpiredResults(t *testing.T) {
 	// Initialize the MicroBatching system with a short TTL to force quick cleanup
 	mb := NewMicroBatching[int](mockProcessor, 1, 10*time.Millisecond, 50*time.Millisecond)
 
-	// Submit a job to the system
+	// Submit a job and wait for it to land in the result store; delivery marks
+	// it consumed automatically, making it eligible for TTL cleanup.
 	job := Job[int]{ID: "expired-job", Data: 100}
-	_, err := mb.SubmitJob(job)
+	resultChan, err := mb.SubmitJob(job)
 	require.NoError(t, err, "Submitting job should not produce an error")
+	<-resultChan
 
-	// Wait a bit longer than the TTL for the cleanup to run
+	// Wait a bit longer than the TTL for the background cleanup loop to run
 	time.Sleep(100 * time.Millisecond)
 
-	// Check if the result has been cleaned up
-	mb.resultsMutex.Lock()
-	defer mb.resultsMutex.Unlock()
-	_, exists := mb.results[job.ID]
-	assert.False(t, exists, "The result for the expired job should have been cleaned up")
+	// Check that the result has been cleaned up from the store
+	_, err = mb.GetResult(job.ID)
+	assert.Error(t, err, "The result for the expired job should have been cleaned up")
 }
 
 func TestShutdownWithResultConsumption(t *testing.T) {
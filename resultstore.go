@@ -0,0 +1,103 @@
+package microbatcher
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ResultStore persists completed JobResult[T] values keyed by job ID, so a
+// client holding only a job ID can retrieve its result via
+// MicroBatching.GetResult even if it was never read from the channel returned
+// by SubmitJob, or if the original process restarted. Implementations must be
+// safe for concurrent use.
+type ResultStore[T any] interface {
+	// Put stores or overwrites the result for result.JobID.
+	Put(result JobResult[T]) error
+	// Get returns the stored result for jobID. The second return value is
+	// false if no result is stored for that ID.
+	Get(jobID string) (JobResult[T], bool, error)
+	// MarkConsumed flags the stored result for jobID as consumed, making it
+	// eligible for cleanup by DeleteExpired once ttl has elapsed since it
+	// completed. It returns an error if no result is stored for jobID.
+	MarkConsumed(jobID string) error
+	// DeleteExpired removes every consumed result whose JobResult.Time is
+	// older than ttl, and returns how many were removed.
+	DeleteExpired(ttl time.Duration) (int, error)
+}
+
+// InMemoryResultStore is the default ResultStore[T]: it keeps results in a
+// process-local map and does not survive a restart.
+type InMemoryResultStore[T any] struct {
+	mu      sync.Mutex
+	results map[string]JobResult[T]
+}
+
+// NewInMemoryResultStore builds an empty InMemoryResultStore.
+func NewInMemoryResultStore[T any]() *InMemoryResultStore[T] {
+	return &InMemoryResultStore[T]{results: make(map[string]JobResult[T])}
+}
+
+func (s *InMemoryResultStore[T]) Put(result JobResult[T]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[result.JobID] = result
+	return nil
+}
+
+func (s *InMemoryResultStore[T]) Get(jobID string) (JobResult[T], bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, ok := s.results[jobID]
+	return result, ok, nil
+}
+
+func (s *InMemoryResultStore[T]) MarkConsumed(jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, ok := s.results[jobID]
+	if !ok {
+		return fmt.Errorf("microbatcher: no stored result for job %q", jobID)
+	}
+	result.Consumed = true
+	s.results[jobID] = result
+	return nil
+}
+
+func (s *InMemoryResultStore[T]) DeleteExpired(ttl time.Duration) (int, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expired := 0
+	for id, result := range s.results {
+		if result.Consumed && now.Sub(result.Time) > ttl {
+			delete(s.results, id)
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// WithResultStore sets the ResultStore[T] used to persist completed results
+// for later retrieval via GetResult. The default is an InMemoryResultStore,
+// matching historical behavior; pass a durable implementation such as
+// SQLResultStore for results to survive a process restart.
+func WithResultStore[T any](store ResultStore[T]) Option[T] {
+	return func(mb *MicroBatching[T]) {
+		mb.resultStore = store
+	}
+}
+
+// GetResult fetches the stored result for jobID from the configured
+// ResultStore, regardless of whether it was ever read from the channel
+// returned by SubmitJob. It returns an error if no result is stored for jobID.
+func (mb *MicroBatching[T]) GetResult(jobID string) (JobResult[T], error) {
+	result, ok, err := mb.resultStore.Get(jobID)
+	if err != nil {
+		return JobResult[T]{}, fmt.Errorf("microbatcher: get result for job %q: %w", jobID, err)
+	}
+	if !ok {
+		return JobResult[T]{}, fmt.Errorf("microbatcher: no stored result for job %q", jobID)
+	}
+	return result, nil
+}
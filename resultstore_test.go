@@ -0,0 +1,105 @@
+package microbatcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryResultStore_PutGetMarkConsumed(t *testing.T) {
+	store := NewInMemoryResultStore[int]()
+
+	_, ok, err := store.Get("missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Put(JobResult[int]{JobID: "1", Success: true, Result: 42, Time: time.Now()}))
+
+	result, ok, err := store.Get("1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 42, result.Result)
+	assert.False(t, result.Consumed)
+
+	require.NoError(t, store.MarkConsumed("1"))
+	result, ok, err = store.Get("1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, result.Consumed)
+
+	assert.Error(t, store.MarkConsumed("missing"))
+}
+
+func TestInMemoryResultStore_DeleteExpired(t *testing.T) {
+	store := NewInMemoryResultStore[int]()
+
+	require.NoError(t, store.Put(JobResult[int]{JobID: "stale", Time: time.Now().Add(-time.Hour)}))
+	require.NoError(t, store.MarkConsumed("stale"))
+
+	require.NoError(t, store.Put(JobResult[int]{JobID: "fresh", Time: time.Now()}))
+	require.NoError(t, store.MarkConsumed("fresh"))
+
+	require.NoError(t, store.Put(JobResult[int]{JobID: "unconsumed", Time: time.Now().Add(-time.Hour)}))
+
+	expired, err := store.DeleteExpired(time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	_, ok, _ := store.Get("stale")
+	assert.False(t, ok)
+	_, ok, _ = store.Get("fresh")
+	assert.True(t, ok)
+	_, ok, _ = store.Get("unconsumed")
+	assert.True(t, ok)
+}
+
+func TestMicroBatching_GetResult(t *testing.T) {
+	mockProcessor := &MockBatchProcessor[int]{
+		ProcessFunc: func(jobs []Job[int]) []JobResult[int] {
+			results := make([]JobResult[int], len(jobs))
+			for i, job := range jobs {
+				results[i] = JobResult[int]{JobID: job.ID, Success: true, Result: job.Data}
+			}
+			return results
+		},
+	}
+	mb := NewMicroBatching[int](mockProcessor, 1, 10*time.Millisecond, time.Minute)
+
+	resultChan, err := mb.SubmitJob(Job[int]{ID: "reattach", Data: 7})
+	require.NoError(t, err)
+	<-resultChan
+
+	result, err := mb.GetResult("reattach")
+	require.NoError(t, err)
+	assert.Equal(t, 7, result.Result)
+
+	_, err = mb.GetResult("never-submitted")
+	assert.Error(t, err)
+}
+
+func TestMicroBatching_DeliveryMarksResultConsumed(t *testing.T) {
+	mockProcessor := &MockBatchProcessor[int]{
+		ProcessFunc: func(jobs []Job[int]) []JobResult[int] {
+			results := make([]JobResult[int], len(jobs))
+			for i, job := range jobs {
+				results[i] = JobResult[int]{JobID: job.ID, Success: true, Result: job.Data}
+			}
+			return results
+		},
+	}
+	mb := NewMicroBatching[int](mockProcessor, 1, 10*time.Millisecond, time.Minute)
+
+	resultChan, err := mb.SubmitJob(Job[int]{ID: "auto-consumed", Data: 1})
+	require.NoError(t, err)
+	<-resultChan
+
+	// Delivery should mark the stored result consumed on its own, without the
+	// caller having to call MarkConsumed, so DeleteExpired can reclaim it once
+	// resultTTL elapses.
+	stored, ok, err := mb.resultStore.Get("auto-consumed")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, stored.Consumed)
+}
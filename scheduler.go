@@ -0,0 +1,207 @@
+package microbatcher
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// SchedulingPolicy selects the order in which queued jobs are drained for batching.
+type SchedulingPolicy int
+
+const (
+	// FIFOPolicy drains jobs in submission order. This is the default and matches
+	// the historical channel-backed behavior of MicroBatching.
+	FIFOPolicy SchedulingPolicy = iota
+	// PriorityPolicy drains higher Job.Priority values first, falling back to
+	// submission order for ties.
+	PriorityPolicy
+	// EDFPolicy (earliest deadline first) drains jobs with the nearest Job.Deadline
+	// first. Jobs with a zero Deadline are treated as having no deadline and are
+	// drained after every job that has one, in submission order.
+	EDFPolicy
+)
+
+// jobEntry wraps a queued Job[T] with the bookkeeping a scheduler needs to order it.
+type jobEntry[T any] struct {
+	job   Job[T]
+	seq   int64
+	index int
+}
+
+// jobHeap is a container/heap.Interface over jobEntry[T], ordered by a policy-specific
+// less function.
+type jobHeap[T any] struct {
+	entries []*jobEntry[T]
+	less    func(a, b *jobEntry[T]) bool
+}
+
+func (h *jobHeap[T]) Len() int { return len(h.entries) }
+
+func (h *jobHeap[T]) Less(i, j int) bool { return h.less(h.entries[i], h.entries[j]) }
+
+func (h *jobHeap[T]) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].index = i
+	h.entries[j].index = j
+}
+
+func (h *jobHeap[T]) Push(x any) {
+	entry := x.(*jobEntry[T])
+	entry.index = len(h.entries)
+	h.entries = append(h.entries, entry)
+}
+
+func (h *jobHeap[T]) Pop() any {
+	old := h.entries
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	h.entries = old[:n-1]
+	return entry
+}
+
+// scheduler is a thread-safe, heap-backed queue of pending jobs. It replaces the
+// plain buffered jobQueue channel so that MicroBatching can drain jobs according to
+// a pluggable SchedulingPolicy instead of strict FIFO order.
+type scheduler[T any] struct {
+	mu            sync.Mutex
+	heap          *jobHeap[T]
+	seq           int64
+	byID          map[string]*jobEntry[T]
+	byCoalesceKey map[string]*jobEntry[T]
+}
+
+// newScheduler builds a scheduler ordered according to policy.
+func newScheduler[T any](policy SchedulingPolicy) *scheduler[T] {
+	var less func(a, b *jobEntry[T]) bool
+	switch policy {
+	case PriorityPolicy:
+		less = func(a, b *jobEntry[T]) bool {
+			if a.job.Priority != b.job.Priority {
+				return a.job.Priority > b.job.Priority
+			}
+			return a.seq < b.seq
+		}
+	case EDFPolicy:
+		less = func(a, b *jobEntry[T]) bool {
+			aZero, bZero := a.job.Deadline.IsZero(), b.job.Deadline.IsZero()
+			switch {
+			case aZero && bZero:
+				return a.seq < b.seq
+			case aZero:
+				return false
+			case bZero:
+				return true
+			case !a.job.Deadline.Equal(b.job.Deadline):
+				return a.job.Deadline.Before(b.job.Deadline)
+			default:
+				return a.seq < b.seq
+			}
+		}
+	default: // FIFOPolicy
+		less = func(a, b *jobEntry[T]) bool {
+			return a.seq < b.seq
+		}
+	}
+
+	s := &scheduler[T]{
+		heap:          &jobHeap[T]{less: less},
+		byID:          make(map[string]*jobEntry[T]),
+		byCoalesceKey: make(map[string]*jobEntry[T]),
+	}
+	heap.Init(s.heap)
+	return s
+}
+
+// push enqueues job, assigning it the next submission sequence number. If merge
+// is non-nil and job.CoalesceKey is non-empty and already matches a job still
+// queued, job is folded into that job instead of being enqueued separately: merge
+// is called with the existing job's Data first and job's Data second, and its
+// result replaces the existing job's Data in place. push then returns the
+// existing job's ID and true. Otherwise job is enqueued normally and push returns
+// job.ID and false.
+func (s *scheduler[T]) push(job Job[T], merge func(existing, incoming T) T) (canonicalID string, coalesced bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if merge != nil && job.CoalesceKey != "" {
+		if existing, ok := s.byCoalesceKey[job.CoalesceKey]; ok {
+			existing.job.Data = merge(existing.job.Data, job.Data)
+			return existing.job.ID, true
+		}
+	}
+
+	s.seq++
+	entry := &jobEntry[T]{job: job, seq: s.seq}
+	heap.Push(s.heap, entry)
+	s.byID[job.ID] = entry
+	if job.CoalesceKey != "" {
+		s.byCoalesceKey[job.CoalesceKey] = entry
+	}
+	return job.ID, false
+}
+
+// pop removes and returns the next job according to the scheduler's policy. The
+// second return value is false if the scheduler is empty.
+func (s *scheduler[T]) pop() (Job[T], bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.heap.Len() == 0 {
+		return Job[T]{}, false
+	}
+	entry := heap.Pop(s.heap).(*jobEntry[T])
+	s.forget(entry)
+	return entry.job, true
+}
+
+// popOldest removes and returns the least-recently-submitted queued job,
+// regardless of the scheduler's ordering policy. It is used to make room under
+// DropOldestPolicy rather than to drain jobs for batching. The second return
+// value is false if the scheduler is empty.
+func (s *scheduler[T]) popOldest() (Job[T], bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var oldest *jobEntry[T]
+	for _, entry := range s.byID {
+		if oldest == nil || entry.seq < oldest.seq {
+			oldest = entry
+		}
+	}
+	if oldest == nil {
+		return Job[T]{}, false
+	}
+	heap.Remove(s.heap, oldest.index)
+	s.forget(oldest)
+	return oldest.job, true
+}
+
+// remove takes a still-queued job out of the scheduler by ID, for example when
+// its submission context is cancelled before it is picked up for batching. The
+// second return value is false if no queued job has that ID.
+func (s *scheduler[T]) remove(jobID string) (Job[T], bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.byID[jobID]
+	if !ok {
+		return Job[T]{}, false
+	}
+	heap.Remove(s.heap, entry.index)
+	s.forget(entry)
+	return entry.job, true
+}
+
+// forget drops entry's bookkeeping from byID and byCoalesceKey. Callers must
+// already have removed entry from the heap itself and hold s.mu.
+func (s *scheduler[T]) forget(entry *jobEntry[T]) {
+	delete(s.byID, entry.job.ID)
+	if entry.job.CoalesceKey != "" {
+		delete(s.byCoalesceKey, entry.job.CoalesceKey)
+	}
+}
+
+// len reports the number of jobs currently queued.
+func (s *scheduler[T]) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.heap.Len()
+}
@@ -0,0 +1,111 @@
+package microbatcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_FIFOPolicy(t *testing.T) {
+	s := newScheduler[int](FIFOPolicy)
+
+	s.push(Job[int]{ID: "1"}, nil)
+	s.push(Job[int]{ID: "2"}, nil)
+	s.push(Job[int]{ID: "3"}, nil)
+
+	var order []string
+	for {
+		job, ok := s.pop()
+		if !ok {
+			break
+		}
+		order = append(order, job.ID)
+	}
+
+	assert.Equal(t, []string{"1", "2", "3"}, order)
+}
+
+func TestScheduler_PriorityPolicy(t *testing.T) {
+	s := newScheduler[int](PriorityPolicy)
+
+	s.push(Job[int]{ID: "low", Priority: 1}, nil)
+	s.push(Job[int]{ID: "high", Priority: 10}, nil)
+	s.push(Job[int]{ID: "mid", Priority: 5}, nil)
+	s.push(Job[int]{ID: "also-low", Priority: 1}, nil)
+
+	var order []string
+	for {
+		job, ok := s.pop()
+		if !ok {
+			break
+		}
+		order = append(order, job.ID)
+	}
+
+	assert.Equal(t, []string{"high", "mid", "low", "also-low"}, order)
+}
+
+func TestScheduler_EDFPolicy(t *testing.T) {
+	s := newScheduler[int](EDFPolicy)
+	now := time.Now()
+
+	s.push(Job[int]{ID: "no-deadline"}, nil)
+	s.push(Job[int]{ID: "later", Deadline: now.Add(time.Hour)}, nil)
+	s.push(Job[int]{ID: "sooner", Deadline: now.Add(time.Minute)}, nil)
+
+	var order []string
+	for {
+		job, ok := s.pop()
+		if !ok {
+			break
+		}
+		order = append(order, job.ID)
+	}
+
+	assert.Equal(t, []string{"sooner", "later", "no-deadline"}, order)
+}
+
+func TestScheduler_Len(t *testing.T) {
+	s := newScheduler[int](FIFOPolicy)
+	assert.Equal(t, 0, s.len())
+
+	s.push(Job[int]{ID: "1"}, nil)
+	s.push(Job[int]{ID: "2"}, nil)
+	assert.Equal(t, 2, s.len())
+
+	_, ok := s.pop()
+	assert.True(t, ok)
+	assert.Equal(t, 1, s.len())
+}
+
+func TestScheduler_PushCoalesces(t *testing.T) {
+	s := newScheduler[int](FIFOPolicy)
+	merge := func(existing, incoming int) int { return existing + incoming }
+
+	id, coalesced := s.push(Job[int]{ID: "a", Data: 1, CoalesceKey: "x"}, merge)
+	assert.Equal(t, "a", id)
+	assert.False(t, coalesced)
+
+	id, coalesced = s.push(Job[int]{ID: "b", Data: 2, CoalesceKey: "x"}, merge)
+	assert.Equal(t, "a", id)
+	assert.True(t, coalesced)
+	assert.Equal(t, 1, s.len())
+
+	job, ok := s.pop()
+	require.True(t, ok)
+	assert.Equal(t, "a", job.ID)
+	assert.Equal(t, 3, job.Data)
+}
+
+func TestScheduler_PopOldest(t *testing.T) {
+	s := newScheduler[int](PriorityPolicy)
+	s.push(Job[int]{ID: "low", Priority: 1}, nil)
+	s.push(Job[int]{ID: "high", Priority: 10}, nil)
+
+	job, ok := s.popOldest()
+	require.True(t, ok)
+	assert.Equal(t, "low", job.ID)
+	assert.Equal(t, 1, s.len())
+}
@@ -0,0 +1,127 @@
+package microbatcher
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SQLResultStore is a ResultStore[T] backed by a SQL database, so completed
+// job results survive a process restart and can be retrieved by clients that
+// reconnect with just a job ID via MicroBatching.GetResult. T is persisted as
+// JSON. The caller is responsible for creating the backing table, e.g. with
+// SQLResultStoreSchema, before passing db to NewSQLResultStore.
+type SQLResultStore[T any] struct {
+	db    *sql.DB
+	table string
+}
+
+// SQLResultStoreSchema returns the CREATE TABLE statement NewSQLResultStore
+// expects to already have been applied for table.
+func SQLResultStoreSchema(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		job_id TEXT PRIMARY KEY,
+		success BOOLEAN NOT NULL,
+		result_json TEXT NOT NULL,
+		error_text TEXT,
+		completed_at TIMESTAMP NOT NULL,
+		consumed BOOLEAN NOT NULL
+	)`, table)
+}
+
+// NewSQLResultStore builds a SQLResultStore that persists into table via db.
+// The caller must create table beforehand, e.g. with SQLResultStoreSchema.
+func NewSQLResultStore[T any](db *sql.DB, table string) *SQLResultStore[T] {
+	return &SQLResultStore[T]{db: db, table: table}
+}
+
+func (s *SQLResultStore[T]) Put(result JobResult[T]) error {
+	resultJSON, err := json.Marshal(result.Result)
+	if err != nil {
+		return fmt.Errorf("microbatcher: marshal result for job %q: %w", result.JobID, err)
+	}
+	var errText sql.NullString
+	if result.Error != nil {
+		errText = sql.NullString{String: result.Error.Error(), Valid: true}
+	}
+
+	_, err = s.db.Exec(
+		fmt.Sprintf(`INSERT INTO %s (job_id, success, result_json, error_text, completed_at, consumed)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (job_id) DO UPDATE SET
+				success = excluded.success,
+				result_json = excluded.result_json,
+				error_text = excluded.error_text,
+				completed_at = excluded.completed_at,
+				consumed = excluded.consumed`, s.table),
+		result.JobID, result.Success, string(resultJSON), errText, result.Time, result.Consumed,
+	)
+	if err != nil {
+		return fmt.Errorf("microbatcher: store result for job %q: %w", result.JobID, err)
+	}
+	return nil
+}
+
+func (s *SQLResultStore[T]) Get(jobID string) (JobResult[T], bool, error) {
+	row := s.db.QueryRow(
+		fmt.Sprintf(`SELECT success, result_json, error_text, completed_at, consumed FROM %s WHERE job_id = ?`, s.table),
+		jobID,
+	)
+
+	var (
+		success     bool
+		resultJSON  string
+		errText     sql.NullString
+		completedAt time.Time
+		consumed    bool
+	)
+	if err := row.Scan(&success, &resultJSON, &errText, &completedAt, &consumed); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return JobResult[T]{}, false, nil
+		}
+		return JobResult[T]{}, false, fmt.Errorf("microbatcher: load result for job %q: %w", jobID, err)
+	}
+
+	var data T
+	if err := json.Unmarshal([]byte(resultJSON), &data); err != nil {
+		return JobResult[T]{}, false, fmt.Errorf("microbatcher: unmarshal result for job %q: %w", jobID, err)
+	}
+
+	result := JobResult[T]{JobID: jobID, Success: success, Result: data, Time: completedAt, Consumed: consumed}
+	if errText.Valid {
+		result.Error = errors.New(errText.String)
+	}
+	return result, true, nil
+}
+
+func (s *SQLResultStore[T]) MarkConsumed(jobID string) error {
+	res, err := s.db.Exec(fmt.Sprintf(`UPDATE %s SET consumed = ? WHERE job_id = ?`, s.table), true, jobID)
+	if err != nil {
+		return fmt.Errorf("microbatcher: mark result consumed for job %q: %w", jobID, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("microbatcher: mark result consumed for job %q: %w", jobID, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("microbatcher: no stored result for job %q", jobID)
+	}
+	return nil
+}
+
+func (s *SQLResultStore[T]) DeleteExpired(ttl time.Duration) (int, error) {
+	res, err := s.db.Exec(
+		fmt.Sprintf(`DELETE FROM %s WHERE consumed = ? AND completed_at < ?`, s.table),
+		true, time.Now().Add(-ttl),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("microbatcher: delete expired results: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("microbatcher: delete expired results: %w", err)
+	}
+	return int(n), nil
+}
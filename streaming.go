@@ -0,0 +1,167 @@
+package microbatcher
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// JobEventType identifies the kind of progress a JobEvent carries.
+type JobEventType int
+
+const (
+	// EventStage announces that a job has entered a named processing stage.
+	EventStage JobEventType = iota
+	// EventLog carries a free-form log line emitted while processing a job.
+	EventLog
+	// EventProgress carries a fractional completion update in the range [0, 1].
+	EventProgress
+	// EventResult carries the terminal JobResult for a job. It is always the
+	// last event delivered for a given job.
+	EventResult
+)
+
+// JobEvent represents a single piece of progress for one job within a batch.
+// StreamingBatchProcessor implementations emit these as they work through a
+// batch, instead of only returning a final JobResult.
+type JobEvent[T any] struct {
+	JobID    string
+	Type     JobEventType
+	Stage    string
+	Log      string
+	Progress float64
+	Result   *JobResult[T]
+	Time     time.Time
+}
+
+// StreamingBatchProcessor is an optional interface a BatchProcessor[T] may also
+// implement to emit intermediate progress for each job in a batch, such as
+// "queued", "running stage X", or "finalizing", in addition to its terminal
+// result. MicroBatching uses this interface instead of BatchProcessor.Process
+// for every batch whenever the processor implements it; the events themselves
+// only reach callers for jobs submitted via SubmitJobStream.
+type StreamingBatchProcessor[T any] interface {
+	// ProcessStream processes jobs, emitting a JobEvent for each one onto events
+	// as work progresses. It must emit exactly one EventResult event per job,
+	// as the terminal event, and must not close events.
+	ProcessStream(ctx context.Context, jobs []Job[T], events chan<- JobEvent[T])
+}
+
+// dispatchEvent fans ev out to the subscriber registered for ev.JobID, if any.
+// Subscribers are only registered for jobs submitted via SubmitJobStream, so
+// events for ordinary SubmitJob callers are simply dropped here.
+func (mb *MicroBatching[T]) dispatchEvent(ev JobEvent[T]) {
+	mb.eventsMutex.Lock()
+	sub, ok := mb.events[ev.JobID]
+	mb.eventsMutex.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case sub <- ev:
+	default:
+		mb.logger.Warnf("Dropping event for JobID %s: subscriber channel full", ev.JobID)
+	}
+}
+
+// processStreamingBatch runs jobs through a StreamingBatchProcessor, fanning
+// out Stage/Log/Progress/Result events to per-job subscribers as they arrive,
+// and returns the terminal results in the same order as jobs.
+func (mb *MicroBatching[T]) processStreamingBatch(ctx context.Context, sp StreamingBatchProcessor[T], jobs []Job[T]) []JobResult[T] {
+	events := make(chan JobEvent[T])
+	done := make(chan struct{})
+
+	resultsByID := make(map[string]JobResult[T], len(jobs))
+	go func() {
+		defer close(done)
+		for ev := range events {
+			ev.Time = time.Now()
+			mb.dispatchEvent(ev)
+			if ev.Type == EventResult && ev.Result != nil {
+				resultsByID[ev.JobID] = *ev.Result
+			}
+		}
+	}()
+
+	sp.ProcessStream(ctx, jobs, events)
+	close(events)
+	<-done
+
+	results := make([]JobResult[T], len(jobs))
+	for i, job := range jobs {
+		if res, ok := resultsByID[job.ID]; ok {
+			results[i] = res
+			continue
+		}
+		mb.logger.Warnf("StreamingBatchProcessor did not emit a result for JobID %s", job.ID)
+		results[i] = JobResult[T]{JobID: job.ID, Success: false, Error: errors.New("no result event received for job")}
+	}
+	return results
+}
+
+// SubmitJobStream submits job like SubmitJob, but returns a channel of
+// JobEvent[T] instead of a channel of JobResult[T]. Callers receive any
+// Stage/Log/Progress events the processor emits (when it implements
+// StreamingBatchProcessor[T]) followed by a single terminal EventResult event,
+// after which the channel is closed. If the processor does not implement
+// StreamingBatchProcessor[T], only the terminal EventResult event is delivered.
+func (mb *MicroBatching[T]) SubmitJobStream(job Job[T]) (<-chan JobEvent[T], error) {
+	// Subscribe before submitting so events emitted by a batch that picks the
+	// job up immediately are not dropped.
+	rawEvents := make(chan JobEvent[T], 16)
+	mb.eventsMutex.Lock()
+	mb.events[job.ID] = rawEvents
+	mb.eventsMutex.Unlock()
+
+	resultChan, err := mb.SubmitJob(job)
+	if err != nil {
+		mb.eventsMutex.Lock()
+		delete(mb.events, job.ID)
+		mb.eventsMutex.Unlock()
+		return nil, err
+	}
+
+	out := make(chan JobEvent[T], 16)
+	go func() {
+		defer close(out)
+		defer func() {
+			mb.eventsMutex.Lock()
+			delete(mb.events, job.ID)
+			mb.eventsMutex.Unlock()
+		}()
+		for {
+			select {
+			case ev, ok := <-rawEvents:
+				if !ok {
+					return
+				}
+				out <- ev
+				if ev.Type == EventResult {
+					return
+				}
+			case result, ok := <-resultChan:
+				// All of the job's events were dispatched before its result was
+				// delivered; forward any still buffered before the result.
+			drain:
+				for {
+					select {
+					case ev := <-rawEvents:
+						out <- ev
+						if ev.Type == EventResult {
+							return
+						}
+					default:
+						break drain
+					}
+				}
+				if !ok {
+					return
+				}
+				out <- JobEvent[T]{JobID: job.ID, Type: EventResult, Result: &result, Time: time.Now()}
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
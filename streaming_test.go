@@ -0,0 +1,95 @@
+package microbatcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// StreamingMockProcessor is a mock StreamingBatchProcessor[T] used to verify
+// that MicroBatching fans out staged events and still delivers a final result.
+type StreamingMockProcessor[T any] struct {
+	StageAndResult func(job Job[T]) (string, JobResult[T])
+}
+
+func (m *StreamingMockProcessor[T]) Process(jobs []Job[T]) []JobResult[T] {
+	results := make([]JobResult[T], len(jobs))
+	for i, job := range jobs {
+		_, result := m.StageAndResult(job)
+		results[i] = result
+	}
+	return results
+}
+
+func (m *StreamingMockProcessor[T]) ProcessStream(ctx context.Context, jobs []Job[T], events chan<- JobEvent[T]) {
+	for _, job := range jobs {
+		stage, result := m.StageAndResult(job)
+		events <- JobEvent[T]{JobID: job.ID, Type: EventStage, Stage: stage}
+		events <- JobEvent[T]{JobID: job.ID, Type: EventProgress, Progress: 1}
+		events <- JobEvent[T]{JobID: job.ID, Type: EventResult, Result: &result}
+	}
+}
+
+func TestSubmitJobStream_DeliversStagedEventsAndResult(t *testing.T) {
+	processor := &StreamingMockProcessor[int]{
+		StageAndResult: func(job Job[int]) (string, JobResult[int]) {
+			return "finalizing", JobResult[int]{JobID: job.ID, Success: true, Result: job.Data}
+		},
+	}
+	mb := NewMicroBatching[int](processor, 1, 10*time.Millisecond, time.Second)
+
+	eventChan, err := mb.SubmitJobStream(Job[int]{ID: "stream-job", Data: 7})
+	require.NoError(t, err)
+
+	var seen []JobEventType
+	var final *JobResult[int]
+	timeout := time.After(time.Second)
+	for final == nil {
+		select {
+		case ev, ok := <-eventChan:
+			if !ok {
+				t.Fatal("event channel closed before a result event was received")
+			}
+			seen = append(seen, ev.Type)
+			if ev.Type == EventResult {
+				final = ev.Result
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for job events")
+		}
+	}
+
+	assert.Equal(t, []JobEventType{EventStage, EventProgress, EventResult}, seen)
+	require.NotNil(t, final)
+	assert.True(t, final.Success)
+	assert.Equal(t, 7, final.Result)
+}
+
+func TestSubmitJobStream_NonStreamingProcessorStillYieldsResult(t *testing.T) {
+	mockProcessor := &MockBatchProcessor[int]{
+		ProcessFunc: func(jobs []Job[int]) []JobResult[int] {
+			results := make([]JobResult[int], len(jobs))
+			for i, job := range jobs {
+				results[i] = JobResult[int]{JobID: job.ID, Success: true, Result: job.Data}
+			}
+			return results
+		},
+	}
+	mb := NewMicroBatching[int](mockProcessor, 1, 10*time.Millisecond, time.Second)
+
+	eventChan, err := mb.SubmitJobStream(Job[int]{ID: "plain-job", Data: 9})
+	require.NoError(t, err)
+
+	select {
+	case ev, ok := <-eventChan:
+		require.True(t, ok)
+		assert.Equal(t, EventResult, ev.Type)
+		require.NotNil(t, ev.Result)
+		assert.True(t, ev.Result.Success)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for job result event")
+	}
+}